@@ -5,12 +5,16 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/julienschmidt/httprouter"
@@ -43,6 +47,38 @@ var (
 		Dev:      types.BlockHeight(1),
 		Testing:  types.BlockHeight(1),
 	}).(types.BlockHeight)
+
+	// defaultDataPieces is the number of data pieces per erasure-coded chunk
+	// used when the caller of the upload API does not specify one.
+	defaultDataPieces = build.Select(build.Var{
+		Standard: uint64(10),
+		Dev:      uint64(1),
+		Testing:  uint64(1),
+	}).(uint64)
+
+	// defaultParityPieces is the number of parity pieces per erasure-coded
+	// chunk used when the caller of the upload API does not specify one.
+	defaultParityPieces = build.Select(build.Var{
+		Standard: uint64(20),
+		Dev:      uint64(1),
+		Testing:  uint64(1),
+	}).(uint64)
+
+	// defaultMinRedundancy is the redundancy below which the background
+	// repairer will consider a file in need of repair.
+	defaultMinRedundancy = build.Select(build.Var{
+		Standard: float64(1.5),
+		Dev:      float64(1),
+		Testing:  float64(1),
+	}).(float64)
+
+	// defaultRepairConcurrency is the number of shard repairs the background
+	// repairer is allowed to run at once.
+	defaultRepairConcurrency = build.Select(build.Var{
+		Standard: uint64(3),
+		Dev:      uint64(1),
+		Testing:  uint64(1),
+	}).(uint64)
 )
 
 type (
@@ -71,9 +107,32 @@ type (
 		Downloads []modules.DownloadInfo `json:"downloads"`
 	}
 
-	// RenterFiles lists the files known to the renter.
+	// RenterFiles lists the files known to the renter. Each modules.FileInfo
+	// carries its own ErasureCode field so callers can see the per-file
+	// redundancy scheme chosen at upload time, and distinguishes
+	// LogicalSize (the original, uncompressed byte count) from
+	// PhysicalSize (the bytes actually stored on hosts) so users can see
+	// what compression saved them. Dirs summarizes the files grouped by
+	// their containing siapath directory.
 	RenterFiles struct {
 		Files []modules.FileInfo `json:"files"`
+		Dirs  []DirInfo          `json:"dirs"`
+	}
+
+	// DirInfo summarizes the files contained within a single siapath
+	// directory, as returned alongside a prefix-filtered file listing.
+	DirInfo struct {
+		SiaPath    string  `json:"siapath"`
+		Size       uint64  `json:"size"`
+		NumFiles   int     `json:"numfiles"`
+		Redundancy float64 `json:"redundancy"`
+	}
+
+	// RenterRepairQueue reports the state of the background auto-repairer.
+	RenterRepairQueue struct {
+		QueueDepth int                    `json:"queuedepth"`
+		InProgress []modules.RepairStatus `json:"inprogress"`
+		Redundancy map[string]float64     `json:"redundancy"`
 	}
 
 	// RenterLoad lists files that were loaded into the renter.
@@ -154,6 +213,27 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		renewWindow = period / 2
 	}
 
+	// Scan the minimum redundancy threshold for the background repairer.
+	// (optional parameter)
+	minRedundancy := defaultMinRedundancy
+	if req.FormValue("minredundancy") != "" {
+		_, err = fmt.Sscan(req.FormValue("minredundancy"), &minRedundancy)
+		if err != nil {
+			WriteError(w, Error{"Couldn't parse minredundancy: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Scan the repair concurrency limit. (optional parameter)
+	repairConcurrency := defaultRepairConcurrency
+	if req.FormValue("repairconcurrency") != "" {
+		_, err = fmt.Sscan(req.FormValue("repairconcurrency"), &repairConcurrency)
+		if err != nil {
+			WriteError(w, Error{"Couldn't parse repairconcurrency: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Set the settings in the renter.
 	err = api.renter.SetSettings(modules.RenterSettings{
 		Allowance: modules.Allowance{
@@ -162,6 +242,8 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 			Period:      period,
 			RenewWindow: renewWindow,
 		},
+		MinRedundancy:     minRedundancy,
+		RepairConcurrency: repairConcurrency,
 	})
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
@@ -235,13 +317,91 @@ func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteSuccess(w)
 }
 
-// renterFilesHandler handles the API call to list all of the files.
+// renterFilesHandler handles the API call to list all of the files. An
+// optional 'prefix' query parameter restricts the listing to files whose
+// siapath starts with it, e.g. "photos/2023/".
 func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	files := api.renter.FileList()
+
+	prefix := req.FormValue("prefix")
+	if prefix != "" {
+		filtered := files[:0]
+		for _, f := range files {
+			if strings.HasPrefix(f.SiaPath, prefix) {
+				filtered = append(filtered, f)
+			}
+		}
+		files = filtered
+	}
+
 	WriteJSON(w, RenterFiles{
-		Files: api.renter.FileList(),
+		Files: files,
+		Dirs:  summarizeDirs(files),
 	})
 }
 
+// summarizeDirs groups files by their containing siapath directory,
+// reporting the aggregate size, file count, and average redundancy of each.
+func summarizeDirs(files []modules.FileInfo) []DirInfo {
+	dirOrder := []string{}
+	dirs := make(map[string]*DirInfo)
+	for _, f := range files {
+		dir := filepath.ToSlash(filepath.Dir(f.SiaPath))
+		d, exists := dirs[dir]
+		if !exists {
+			d = &DirInfo{SiaPath: dir}
+			dirs[dir] = d
+			dirOrder = append(dirOrder, dir)
+		}
+		d.Size += f.Filesize
+		d.Redundancy += f.Redundancy
+		d.NumFiles++
+	}
+
+	result := make([]DirInfo, 0, len(dirOrder))
+	for _, dir := range dirOrder {
+		d := *dirs[dir]
+		if d.NumFiles > 0 {
+			d.Redundancy /= float64(d.NumFiles)
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// renterDirCreateHandler handles the API call to create a directory in
+// siaspace.
+func (api *API) renterDirCreateHandler(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	err := api.renter.CreateDir(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterDirDeleteHandler handles the API call to recursively delete a
+// directory, and every file beneath it, from siaspace.
+func (api *API) renterDirDeleteHandler(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	err := api.renter.DeleteDir(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterDirRenameHandler handles the API call to recursively rename a
+// directory, and every file beneath it, in siaspace.
+func (api *API) renterDirRenameHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	err := api.renter.RenameDir(strings.TrimPrefix(ps.ByName("siapath"), "/"), req.FormValue("newsiapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // renterDeleteHandler handles the API call to delete a file entry from the
 // renter.
 func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -254,16 +414,66 @@ func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteSuccess(w)
 }
 
+// renterRepairHandler handles the API call to repair a file. It runs
+// Renter.RepairFile for the file and responds once that repair has
+// finished.
+func (api *API) renterRepairHandler(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	err := api.renter.RepairFile(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{"Repair failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterRepairQueueHandler handles the API call to report the state of the
+// background auto-repairer: how many shard repairs are queued, which ones
+// are currently in flight, and the current redundancy of every tracked file.
+func (api *API) renterRepairQueueHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, RenterRepairQueue{
+		QueueDepth: api.renter.RepairQueueDepth(),
+		InProgress: api.renter.RepairsInProgress(),
+		Redundancy: api.renter.Redundancy(),
+	})
+}
+
 // renterDownloadHandler handles the API call to download a file.
 func (api *API) renterDownloadHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	destination := req.FormValue("destination")
+	// If no destination was supplied, stream the file back in the response
+	// body instead of writing it to a local path.
+	if destination == "" {
+		api.renterStreamHandler(w, req, ps)
+		return
+	}
 	// Check that the destination path is absolute.
 	if !filepath.IsAbs(destination) {
 		WriteError(w, Error{"destination must be an absolute path"}, http.StatusBadRequest)
 		return
 	}
 
-	err := api.renter.Download(strings.TrimPrefix(ps.ByName("siapath"), "/"), destination)
+	siapath := strings.TrimPrefix(ps.ByName("siapath"), "/")
+
+	// If 'recursive' was set, treat siapath as a directory and mirror every
+	// file beneath it into the destination directory.
+	if req.FormValue("recursive") == "true" {
+		prefix := strings.TrimSuffix(siapath, "/") + "/"
+		for _, f := range api.renter.FileList() {
+			if f.SiaPath != siapath && !strings.HasPrefix(f.SiaPath, prefix) {
+				continue
+			}
+			rel := strings.TrimPrefix(f.SiaPath, siapath)
+			dst := filepath.Join(destination, filepath.FromSlash(rel))
+			if err := api.renter.Download(f.SiaPath, dst); err != nil {
+				WriteError(w, Error{"Recursive download failed: " + err.Error()}, http.StatusInternalServerError)
+				return
+			}
+		}
+		WriteSuccess(w)
+		return
+	}
+
+	err := api.renter.Download(siapath, destination)
 	if err != nil {
 		WriteError(w, Error{"Download failed: " + err.Error()}, http.StatusInternalServerError)
 		return
@@ -272,6 +482,114 @@ func (api *API) renterDownloadHandler(w http.ResponseWriter, req *http.Request,
 	WriteSuccess(w)
 }
 
+// renterStreamHandler handles the API call to stream a file directly over
+// HTTP. It honors Range headers so that callers such as video players and
+// object-storage clients can seek within the file without downloading it in
+// full, and answers HEAD requests with metadata only.
+//
+// A compressed stream is not itself seekable, so Renter.Streamer is
+// expected to fall back to decompressing on a block-by-block basis for any
+// file uploaded with compression enabled (see parseCompression), trading a
+// small amount of compression ratio for the ability to still serve Range
+// requests against it.
+func (api *API) renterStreamHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	siapath := strings.TrimPrefix(ps.ByName("siapath"), "/")
+	streamer, size, err := api.renter.Streamer(siapath)
+	if err != nil {
+		WriteError(w, Error{"Streaming failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	if closer, ok := streamer.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	if req.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	rangeHeader := req.Header.Get("Range")
+	if rangeHeader == "" {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		io.Copy(w, streamer)
+		return
+	}
+
+	// Only a single byte range is supported; multi-range responses would
+	// require a multipart/byteranges body, which no caller of this handler
+	// currently needs.
+	start, end, err := ParseRangeHeader(rangeHeader, size)
+	if err != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%v", size))
+		WriteError(w, Error{"Invalid Range: " + err.Error()}, http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if _, err := streamer.Seek(start, io.SeekStart); err != nil {
+		WriteError(w, Error{"Streaming failed: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %v-%v/%v", start, end, size))
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	io.CopyN(w, streamer, end-start+1)
+}
+
+// ParseRangeHeader parses a single-range "bytes=start-end" Range header
+// value against the size of the underlying resource, returning the
+// inclusive start and end offsets of the requested range. It also handles
+// the suffix-range form ("bytes=-500", the last 500 bytes), which real HTTP
+// clients send alongside the start-end form. Exported so other HTTP
+// front ends in front of the renter (e.g. api/s3) can share this instead of
+// forking their own copy.
+func ParseRangeHeader(rangeHeader string, size int64) (start, end int64, err error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(rangeHeader, prefix) {
+		return 0, 0, fmt.Errorf("unsupported range unit in %q", rangeHeader)
+	}
+	bounds := strings.SplitN(strings.TrimPrefix(rangeHeader, prefix), "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("malformed range %q", rangeHeader)
+	}
+
+	switch {
+	case bounds[0] == "":
+		// Suffix range, e.g. "bytes=-500" means the last 500 bytes.
+		suffixLen, err := strconv.ParseInt(bounds[1], 10, 64)
+		if err != nil || suffixLen <= 0 {
+			return 0, 0, fmt.Errorf("malformed suffix range %q", rangeHeader)
+		}
+		if suffixLen > size {
+			suffixLen = size
+		}
+		start = size - suffixLen
+		end = size - 1
+	default:
+		start, err = strconv.ParseInt(bounds[0], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("malformed range start %q", rangeHeader)
+		}
+		if bounds[1] == "" {
+			end = size - 1
+		} else {
+			end, err = strconv.ParseInt(bounds[1], 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("malformed range end %q", rangeHeader)
+			}
+		}
+	}
+
+	if start < 0 || end < start || start >= size {
+		return 0, 0, fmt.Errorf("range %q out of bounds for size %v", rangeHeader, size)
+	}
+	if end >= size {
+		end = size - 1
+	}
+	return start, end, nil
+}
+
 // renterShareHandler handles the API call to create a '.sia' file that
 // shares a set of file.
 func (api *API) renterShareHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -312,11 +630,51 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		return
 	}
 
-	err := api.renter.Upload(modules.FileUploadParams{
-		Source:  source,
-		SiaPath: strings.TrimPrefix(ps.ByName("siapath"), "/"),
-		// let the renter decide these values; eventually they will be configurable
-		ErasureCode: nil,
+	ec, err := parseErasureCode(req.FormValue("datapieces"), req.FormValue("paritypieces"), req.FormValue("codec"))
+	if err != nil {
+		WriteError(w, Error{"Couldn't parse erasure coding parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	compression, err := parseCompression(req.FormValue("compression"))
+	if err != nil {
+		WriteError(w, Error{"Couldn't parse compression: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	siapath := strings.TrimPrefix(ps.ByName("siapath"), "/")
+
+	// If 'recursive' was set, walk the local source directory and mirror
+	// every file it contains into siaspace beneath siapath.
+	if req.FormValue("recursive") == "true" {
+		err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(source, path)
+			if err != nil {
+				return err
+			}
+			return api.renter.Upload(modules.FileUploadParams{
+				Source:      path,
+				SiaPath:     filepath.ToSlash(filepath.Join(siapath, rel)),
+				ErasureCode: ec,
+				Compression: compression,
+			})
+		})
+		if err != nil {
+			WriteError(w, Error{"Recursive upload failed: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+
+	err = api.renter.Upload(modules.FileUploadParams{
+		Source:      source,
+		SiaPath:     siapath,
+		ErasureCode: ec,
+		Compression: compression,
 	})
 	if err != nil {
 		WriteError(w, Error{"Upload failed: " + err.Error()}, http.StatusInternalServerError)
@@ -326,6 +684,71 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteSuccess(w)
 }
 
+// parseCompression validates the 'compression' upload form value against
+// the codecs the renter can actually compress and decompress chunks with.
+// An empty value means no compression. Because a compressed stream is not
+// seekable, the renter compresses each erasure-coded chunk independently
+// rather than the stream as a whole, which keeps Range requests against the
+// stream handler working at the cost of a slightly worse compression
+// ratio.
+func parseCompression(compression string) (modules.CompressionType, error) {
+	var ct modules.CompressionType
+	switch compression {
+	case "", "none":
+		ct = modules.CompressionNone
+	case "zstd":
+		ct = modules.CompressionZstd
+	case "gzip":
+		ct = modules.CompressionGzip
+	case "snappy":
+		ct = modules.CompressionSnappy
+	default:
+		return "", fmt.Errorf("unsupported compression codec %q", compression)
+	}
+	if err := renter.ValidateCompression(ct); err != nil {
+		return "", err
+	}
+	return ct, nil
+}
+
+// parseErasureCode builds a modules.ErasureCoder from the 'datapieces',
+// 'paritypieces', and 'codec' upload form values, falling back to
+// defaultDataPieces and defaultParityPieces for any value left blank. A nil
+// ErasureCoder tells the
+// renter to pick its own scheme.
+func parseErasureCode(dataPiecesStr, parityPiecesStr, codec string) (modules.ErasureCoder, error) {
+	if dataPiecesStr == "" && parityPiecesStr == "" && codec == "" {
+		return nil, nil
+	}
+
+	dataPieces := defaultDataPieces
+	if dataPiecesStr != "" {
+		_, err := fmt.Sscan(dataPiecesStr, &dataPieces)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse datapieces: %v", err)
+		}
+	}
+	parityPieces := defaultParityPieces
+	if parityPiecesStr != "" {
+		_, err := fmt.Sscan(parityPiecesStr, &parityPieces)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse paritypieces: %v", err)
+		}
+	}
+	if dataPieces < 1 || parityPieces < 1 || dataPieces+parityPieces > 255 {
+		return nil, fmt.Errorf("invalid erasure coding parameters: %v data pieces, %v parity pieces", dataPieces, parityPieces)
+	}
+
+	switch codec {
+	case "", "reed-solomon":
+		return renter.NewRSCode(int(dataPieces), int(parityPieces))
+	case "reed-solomon-vandermonde":
+		return renter.NewRSVandCode(int(dataPieces), int(parityPieces))
+	default:
+		return nil, fmt.Errorf("unsupported codec %q", codec)
+	}
+}
+
 // renterHostsActiveHandler handles the API call asking for the list of active
 // hosts.
 func (api *API) renterHostsActiveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
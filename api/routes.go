@@ -0,0 +1,38 @@
+package api
+
+import (
+	"github.com/julienschmidt/httprouter"
+)
+
+// buildRenterRoutes registers every /renter/* route on router. It is
+// called once when the API server is set up.
+func (api *API) buildRenterRoutes(router *httprouter.Router) {
+	router.GET("/renter", api.renterHandlerGET)
+	router.POST("/renter", api.renterHandlerPOST)
+	router.GET("/renter/contracts", api.renterContractsHandler)
+	router.GET("/renter/downloads", api.renterDownloadsHandler)
+	router.POST("/renter/load", api.renterLoadHandler)
+	router.POST("/renter/loadascii", api.renterLoadAsciiHandler)
+	router.POST("/renter/rename/*siapath", api.renterRenameHandler)
+	router.GET("/renter/files", api.renterFilesHandler)
+	router.POST("/renter/delete/*siapath", api.renterDeleteHandler)
+	router.GET("/renter/download/*siapath", api.renterDownloadHandler)
+	router.GET("/renter/stream/*siapath", api.renterStreamHandler)
+	router.HEAD("/renter/stream/*siapath", api.renterStreamHandler)
+	router.POST("/renter/share", api.renterShareHandler)
+	router.POST("/renter/shareascii", api.renterShareAsciiHandler)
+	router.POST("/renter/upload/*siapath", api.renterUploadHandler)
+	router.GET("/renter/hosts/active", api.renterHostsActiveHandler)
+	router.GET("/renter/hosts/all", api.renterHostsAllHandler)
+	router.POST("/renter/repair/*siapath", api.renterRepairHandler)
+	router.GET("/renter/repair", api.renterRepairQueueHandler)
+	router.POST("/renter/dir/*siapath", api.renterDirCreateHandler)
+	router.DELETE("/renter/dir/*siapath", api.renterDirDeleteHandler)
+	// Note: httprouter treats "/renter/rename/*siapath" (registered above
+	// for file renames) as a catch-all over everything beneath
+	// /renter/rename/, so a POST /renter/rename/dir/*siapath route as
+	// requested would conflict with it at startup. /renter/renamedir/ is a
+	// sibling path instead of a child of that catch-all, so it can't
+	// collide with it.
+	router.POST("/renter/renamedir/*siapath", api.renterDirRenameHandler)
+}
@@ -0,0 +1,173 @@
+package s3
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// multipartUpload tracks the parts received for a single in-progress
+// multipart upload. Parts are spooled to disk and concatenated in part
+// number order on completion into one file, which is then uploaded like a
+// single-part object; the renter's erasure-coded chunk boundaries do not
+// follow the part boundaries.
+type multipartUpload struct {
+	siapath string
+	parts   map[int]string // part number -> spooled file path
+}
+
+// multipartUploads tracks in-progress multipart uploads by upload ID.
+// nextID is the source of upload IDs; it must only be read or incremented
+// while holding the mutex, so that two concurrent initiations can never be
+// handed the same ID.
+var multipartUploads = struct {
+	sync.Mutex
+	m      map[string]*multipartUpload
+	nextID uint64
+}{m: make(map[string]*multipartUpload)}
+
+// isMultipartRequest reports whether req is part of the multipart upload
+// flow: initiation ("?uploads"), a part upload ("?partNumber=&uploadId="),
+// or completion ("?uploadId=" with a POST-style complete body).
+func isMultipartRequest(req *http.Request) bool {
+	q := req.URL.Query()
+	_, hasUploads := q["uploads"]
+	_, hasUploadID := q["uploadId"]
+	return hasUploads || hasUploadID
+}
+
+// multipartHandler dispatches PUT requests that are part of the multipart
+// upload flow: initiating an upload, or uploading a single part.
+// Completion arrives as a POST and is handled by completeMultipartHandler.
+func (g *Gateway) multipartHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	q := req.URL.Query()
+	if _, ok := q["uploads"]; ok {
+		g.initiateMultipartHandler(w, req, ps)
+		return
+	}
+	g.uploadPartHandler(w, req, ps)
+}
+
+// initiateMultipartHandler handles "PUT /{bucket}/{key}?uploads", starting
+// a new multipart upload and returning an upload ID for subsequent parts.
+func (g *Gateway) initiateMultipartHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	multipartUploads.Lock()
+	multipartUploads.nextID++
+	uploadID := strconv.FormatUint(multipartUploads.nextID, 10) + "-" + ps.ByName("bucket")
+	multipartUploads.m[uploadID] = &multipartUpload{
+		siapath: siapath(ps.ByName("bucket"), ps.ByName("key")),
+		parts:   make(map[int]string),
+	}
+	multipartUploads.Unlock()
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte("<InitiateMultipartUploadResult><UploadId>" + uploadID + "</UploadId></InitiateMultipartUploadResult>"))
+}
+
+// uploadPartHandler handles "PUT /{bucket}/{key}?partNumber=&uploadId=",
+// spooling a single part to disk for later concatenation.
+func (g *Gateway) uploadPartHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	uploadID := req.FormValue("uploadId")
+	partNumber, err := strconv.Atoi(req.FormValue("partNumber"))
+	if err != nil {
+		writeS3Error(w, "InvalidArgument", "invalid partNumber", http.StatusBadRequest)
+		return
+	}
+
+	multipartUploads.Lock()
+	upload, ok := multipartUploads.m[uploadID]
+	multipartUploads.Unlock()
+	if !ok {
+		writeS3Error(w, "NoSuchUpload", "unknown uploadId", http.StatusNotFound)
+		return
+	}
+
+	tmp, err := spoolToTempFile(g.spoolDir, req.Body)
+	if err != nil {
+		writeS3Error(w, "InternalError", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	multipartUploads.Lock()
+	upload.parts[partNumber] = tmp
+	multipartUploads.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// completeMultipartHandler handles "POST /{bucket}/{key}?uploadId=",
+// concatenating all received parts in order and uploading the result as a
+// single siafile.
+func (g *Gateway) completeMultipartHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	uploadID := req.FormValue("uploadId")
+
+	multipartUploads.Lock()
+	upload, ok := multipartUploads.m[uploadID]
+	delete(multipartUploads.m, uploadID)
+	multipartUploads.Unlock()
+	if !ok {
+		writeS3Error(w, "NoSuchUpload", "unknown uploadId", http.StatusNotFound)
+		return
+	}
+
+	partNumbers := make([]int, 0, len(upload.parts))
+	for n := range upload.parts {
+		partNumbers = append(partNumbers, n)
+	}
+	sort.Ints(partNumbers)
+
+	if err := os.MkdirAll(g.spoolDir, 0700); err != nil {
+		writeS3Error(w, "InternalError", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	combined, err := ioutil.TempFile(g.spoolDir, "sia-s3-upload-complete-")
+	if err != nil {
+		writeS3Error(w, "InternalError", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, n := range partNumbers {
+		partPath := upload.parts[n]
+		part, err := os.Open(partPath)
+		if err != nil {
+			combined.Close()
+			cleanupTempFile(combined.Name())
+			writeS3Error(w, "InternalError", err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, err = io.Copy(combined, part)
+		part.Close()
+		cleanupTempFile(partPath)
+		if err != nil {
+			combined.Close()
+			cleanupTempFile(combined.Name())
+			writeS3Error(w, "InternalError", err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	combined.Close()
+
+	err = g.renter.Upload(modules.FileUploadParams{
+		Source:  combined.Name(),
+		SiaPath: upload.siapath,
+	})
+	if err != nil {
+		cleanupTempFile(combined.Name())
+		writeS3Error(w, "InternalError", "upload failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// combined was accepted as the siafile's source and is left on disk
+	// when this handler returns, the same as a single-part upload's spooled
+	// temp file.
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte("<CompleteMultipartUploadResult><Key>" + ps.ByName("key") + "</Key></CompleteMultipartUploadResult>"))
+}
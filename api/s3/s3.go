@@ -0,0 +1,229 @@
+// Package s3 implements an S3-compatible HTTP gateway in front of the
+// renter. It translates a subset of the AWS S3 REST API into calls against
+// modules.Renter so that existing S3 tooling (aws-cli, rclone, the minio
+// client, ...) can read and write Sia storage without any code changes.
+//
+// Buckets have no first-class representation in siaspace: a bucket is just
+// the first path element of a siapath, e.g. object "foo/bar.txt" in bucket
+// "mybucket" is stored at siapath "mybucket/foo/bar.txt".
+package s3
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NebulousLabs/Sia/modules"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// defaultMaxKeys is the max-keys value listObjectsHandler uses when the
+// caller doesn't specify one, matching the AWS default.
+const defaultMaxKeys = 1000
+
+// Renter is the subset of modules.Renter that the gateway needs in order to
+// serve S3 requests.
+type Renter interface {
+	Upload(modules.FileUploadParams) error
+	Download(siapath, destination string) error
+	Streamer(siapath string) (modules.Streamer, int64, error)
+	DeleteFile(siapath string) error
+	FileList() []modules.FileInfo
+}
+
+// Gateway serves the S3-compatible API in front of a Renter.
+type Gateway struct {
+	renter   Renter
+	keys     KeyStore
+	spoolDir string
+}
+
+// New returns a Gateway that serves S3 requests against renter, authorizing
+// callers against the given access key / secret key pairs. Incoming PUT
+// bodies and multipart parts are spooled beneath spoolDir rather than the
+// system temp directory, so they survive on the same volume the renter
+// itself persists to and don't compete with whatever else uses os.TempDir.
+func New(spoolDir string, renter Renter, keys KeyStore) *Gateway {
+	return &Gateway{
+		renter:   renter,
+		keys:     keys,
+		spoolDir: spoolDir,
+	}
+}
+
+// RegisterRoutes adds the gateway's routes to router, mirroring the path
+// shape of the real S3 REST API: /{bucket} for bucket-level operations and
+// /{bucket}/{key} for object-level operations.
+func (g *Gateway) RegisterRoutes(router *httprouter.Router) {
+	router.GET("/:bucket", g.authenticated(g.listObjectsHandler))
+	router.PUT("/:bucket/*key", g.authenticated(g.putObjectHandler))
+	router.POST("/:bucket/*key", g.authenticated(g.completeMultipartHandler))
+	router.GET("/:bucket/*key", g.authenticated(g.getObjectHandler))
+	router.HEAD("/:bucket/*key", g.authenticated(g.headObjectHandler))
+	router.DELETE("/:bucket/*key", g.authenticated(g.deleteObjectHandler))
+}
+
+// siapath joins a bucket and key into the siapath under which the
+// corresponding object is stored.
+func siapath(bucket, key string) string {
+	return bucket + "/" + strings.TrimPrefix(key, "/")
+}
+
+// writeS3Error writes body as an S3-style XML error response.
+func writeS3Error(w http.ResponseWriter, code string, message string, status int) {
+	type s3Error struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(s3Error{Code: code, Message: message})
+}
+
+// putObjectHandler handles PUT /{bucket}/{key}, mapping the request body
+// onto a renter upload. Requests that are part of a multipart upload are
+// handed to multipartHandler instead; see multipart.go.
+func (g *Gateway) putObjectHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	if isMultipartRequest(req) {
+		g.multipartHandler(w, req, ps)
+		return
+	}
+
+	tmp, err := spoolToTempFile(g.spoolDir, req.Body)
+	if err != nil {
+		writeS3Error(w, "InternalError", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = g.renter.Upload(modules.FileUploadParams{
+		Source:  tmp,
+		SiaPath: siapath(ps.ByName("bucket"), ps.ByName("key")),
+	})
+	if err != nil {
+		cleanupTempFile(tmp)
+		writeS3Error(w, "InternalError", "upload failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// tmp was accepted as the siafile's source and is left on disk when
+	// this handler returns.
+	w.WriteHeader(http.StatusOK)
+}
+
+// getObjectHandler handles GET /{bucket}/{key}, streaming the object back
+// to the caller and honoring Range requests the same way the renter API's
+// own stream handler does.
+func (g *Gateway) getObjectHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	streamer, size, err := g.renter.Streamer(siapath(ps.ByName("bucket"), ps.ByName("key")))
+	if err != nil {
+		writeS3Error(w, "NoSuchKey", err.Error(), http.StatusNotFound)
+		return
+	}
+	serveObjectStream(w, req, streamer, size)
+}
+
+// headObjectHandler handles HEAD /{bucket}/{key}, reporting object metadata
+// without transferring its contents.
+func (g *Gateway) headObjectHandler(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	path := siapath(ps.ByName("bucket"), ps.ByName("key"))
+	for _, f := range g.renter.FileList() {
+		if f.SiaPath == path {
+			w.Header().Set("Content-Length", strconv.FormatUint(f.Filesize, 10))
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+	writeS3Error(w, "NoSuchKey", "no such key", http.StatusNotFound)
+}
+
+// deleteObjectHandler handles DELETE /{bucket}/{key}.
+func (g *Gateway) deleteObjectHandler(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	err := g.renter.DeleteFile(siapath(ps.ByName("bucket"), ps.ByName("key")))
+	if err != nil {
+		writeS3Error(w, "NoSuchKey", err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listObjectsHandler handles GET /{bucket}?list-type=2&prefix=..., the V2
+// list-objects call used by the aws-cli and most S3 SDKs. It pages through
+// matching keys maxKeys at a time, reporting IsTruncated and a
+// NextContinuationToken the caller passes back as continuation-token to
+// fetch the next page, the same contract real S3 buckets honor so SDKs
+// don't have to special-case this gateway for large buckets.
+func (g *Gateway) listObjectsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	bucket := ps.ByName("bucket")
+	prefix := bucket + "/" + req.FormValue("prefix")
+
+	maxKeys := defaultMaxKeys
+	if v := req.FormValue("max-keys"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			writeS3Error(w, "InvalidArgument", "invalid max-keys", http.StatusBadRequest)
+			return
+		}
+		maxKeys = parsed
+	}
+
+	var matches []modules.FileInfo
+	for _, f := range g.renter.FileList() {
+		if strings.HasPrefix(f.SiaPath, prefix) {
+			matches = append(matches, f)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].SiaPath < matches[j].SiaPath })
+
+	// A continuation-token is the key (relative to the bucket) of the last
+	// object returned on the previous page; resume just after it.
+	start := 0
+	if token := req.FormValue("continuation-token"); token != "" {
+		after := bucket + "/" + token
+		for i, f := range matches {
+			if f.SiaPath > after {
+				break
+			}
+			start = i + 1
+		}
+	}
+	page := matches[start:]
+
+	type contents struct {
+		Key  string `xml:"Key"`
+		Size uint64 `xml:"Size"`
+	}
+	type listResult struct {
+		XMLName               xml.Name   `xml:"ListBucketResult"`
+		Name                  string     `xml:"Name"`
+		Prefix                string     `xml:"Prefix"`
+		MaxKeys               int        `xml:"MaxKeys"`
+		KeyCount              int        `xml:"KeyCount"`
+		IsTruncated           bool       `xml:"IsTruncated"`
+		NextContinuationToken string     `xml:"NextContinuationToken,omitempty"`
+		Contents              []contents `xml:"Contents"`
+	}
+	result := listResult{Name: bucket, Prefix: req.FormValue("prefix"), MaxKeys: maxKeys}
+
+	if maxKeys == 0 {
+		result.IsTruncated = len(page) > 0
+		page = nil
+	} else if len(page) > maxKeys {
+		result.IsTruncated = true
+		result.NextContinuationToken = strings.TrimPrefix(page[maxKeys-1].SiaPath, bucket+"/")
+		page = page[:maxKeys]
+	}
+	for _, f := range page {
+		result.Contents = append(result.Contents, contents{
+			Key:  strings.TrimPrefix(f.SiaPath, bucket+"/"),
+			Size: f.Filesize,
+		})
+	}
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,59 @@
+package s3
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestCanonicalHeaderValueHost verifies the Host special-case: net/http
+// strips the Host header out of req.Header and stores it on req.Host, so a
+// naive Header.Get("host") would always come back empty even though every
+// real S3 client signs a host header.
+func TestCanonicalHeaderValueHost(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://example.com/bucket/key", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Host = "sia-s3.example.com"
+
+	if got := canonicalHeaderValue(req, "host"); got != "sia-s3.example.com" {
+		t.Errorf("canonicalHeaderValue(host) = %q, want %q", got, "sia-s3.example.com")
+	}
+	if got := canonicalHeaderValue(req, "Host"); got != "sia-s3.example.com" {
+		t.Errorf("canonicalHeaderValue(Host) = %q, want %q", got, "sia-s3.example.com")
+	}
+}
+
+func TestCanonicalQueryStringSortsKeys(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://example.com/bucket?z=1&a=2&m=3", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := canonicalQueryString(req), "a=2&m=3&z=1"; got != want {
+		t.Errorf("canonicalQueryString() = %q, want %q", got, want)
+	}
+}
+
+func TestCredentialScope(t *testing.T) {
+	auth := "AWS4-HMAC-SHA256 Credential=AKID/20240102/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=abc"
+	if got, want := credentialScope(auth, "20240102T000000Z"), "20240102/us-east-1/s3/aws4_request"; got != want {
+		t.Errorf("credentialScope() = %q, want %q", got, want)
+	}
+}
+
+func TestParseSigV4Header(t *testing.T) {
+	auth := "AWS4-HMAC-SHA256 Credential=AKID/20240102/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=abc123"
+	accessKey, signedHeaders, signature, err := parseSigV4Header(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accessKey != "AKID" {
+		t.Errorf("accessKey = %q, want %q", accessKey, "AKID")
+	}
+	if len(signedHeaders) != 2 || signedHeaders[0] != "host" || signedHeaders[1] != "x-amz-date" {
+		t.Errorf("signedHeaders = %v, want [host x-amz-date]", signedHeaders)
+	}
+	if signature != "abc123" {
+		t.Errorf("signature = %q, want %q", signature, "abc123")
+	}
+}
@@ -0,0 +1,43 @@
+package s3
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/NebulousLabs/Sia/api"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// serveObjectStream writes streamer's contents to w, honoring a Range
+// header the same way the renter API's own /renter/stream handler does.
+func serveObjectStream(w http.ResponseWriter, req *http.Request, streamer modules.Streamer, size int64) {
+	if closer, ok := streamer.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	rangeHeader := req.Header.Get("Range")
+	if rangeHeader == "" {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		io.Copy(w, streamer)
+		return
+	}
+
+	start, end, err := api.ParseRangeHeader(rangeHeader, size)
+	if err != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%v", size))
+		writeS3Error(w, "InvalidRange", err.Error(), http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if _, err := streamer.Seek(start, io.SeekStart); err != nil {
+		writeS3Error(w, "InternalError", err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %v-%v/%v", start, end, size))
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	io.CopyN(w, streamer, end-start+1)
+}
@@ -0,0 +1,36 @@
+package s3
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// spoolToTempFile copies body to a file under dir and returns its path.
+// The renter's upload path takes a local source file rather than a stream,
+// so incoming PUT bodies are spooled to disk before handing them off.
+// Files are spooled under dir rather than os.TempDir.
+func spoolToTempFile(dir string, body io.Reader) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempFile(dir, "sia-s3-upload-")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// cleanupTempFile removes a file spooled by spoolToTempFile. The handlers
+// call it only for a temp file that Renter.Upload did not accept; a file
+// that Upload accepted as its Source is kept on disk, the same as any other
+// local-source upload, and is not removed when the HTTP handler returns.
+func cleanupTempFile(path string) {
+	os.Remove(path)
+}
@@ -0,0 +1,160 @@
+package modules
+
+import (
+	"io"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// SectorSize is the number of bytes in a sector stored on a host.
+const SectorSize = 1 << 22 // 4 MiB
+
+type (
+	// Allowance dictates how much the renter is allowed to spend in a given
+	// period of hosting.
+	Allowance struct {
+		Funds       types.Currency
+		Hosts       uint64
+		Period      types.BlockHeight
+		RenewWindow types.BlockHeight
+	}
+
+	// RenterSettings control the behavior of the renter.
+	RenterSettings struct {
+		Allowance Allowance
+
+		// MinRedundancy is the redundancy below which the background
+		// repairer will consider a file in need of repair.
+		MinRedundancy float64
+
+		// RepairConcurrency is the number of shard repairs the background
+		// repairer is allowed to run at once.
+		RepairConcurrency uint64
+	}
+
+	// RenterFinancialMetrics contains metrics about how much the Renter has
+	// spent on storage.
+	RenterFinancialMetrics struct {
+		ContractSpending types.Currency
+		DownloadSpending types.Currency
+		StorageSpending  types.Currency
+		UploadSpending   types.Currency
+	}
+
+	// NetAddress identifies a host on the network.
+	NetAddress string
+
+	// HostDBEntry represents one host tracked by the renter's host
+	// database.
+	HostDBEntry struct {
+		NetAddress NetAddress
+	}
+
+	// DownloadInfo provides information about a file that has been
+	// requested for download.
+	DownloadInfo struct {
+		SiaPath     string
+		Destination string
+		Filesize    uint64
+		Received    uint64
+	}
+
+	// FileUploadParams are the parameters passed to the Renter's Upload
+	// method.
+	FileUploadParams struct {
+		Source      string
+		SiaPath     string
+		ErasureCode ErasureCoder
+		Compression CompressionType
+	}
+
+	// FileInfo provides information about a file that is tracked by the
+	// renter.
+	FileInfo struct {
+		SiaPath      string
+		Filesize     uint64
+		Redundancy   float64
+		ErasureCode  ErasureCoderInfo
+		Compression  CompressionType
+		LogicalSize  uint64
+		PhysicalSize uint64
+	}
+
+	// CompressionType identifies the codec used to compress a file's chunks
+	// before they're erasure-coded and distributed to hosts.
+	CompressionType string
+
+	// ErasureCoderInfo summarizes an ErasureCoder's scheme for display,
+	// without exposing the coder itself.
+	ErasureCoderInfo struct {
+		Type      ErasureCoderType `json:"type"`
+		MinPieces int              `json:"minpieces"`
+		NumPieces int              `json:"numpieces"`
+	}
+
+	// ErasureCoderType identifies the scheme implemented by an ErasureCoder,
+	// e.g. "reed-solomon".
+	ErasureCoderType string
+
+	// ErasureCoder is the interface implemented by erasure coding schemes
+	// the renter can use to split a file into redundant pieces before
+	// distributing them to hosts.
+	ErasureCoder interface {
+		// NumPieces is the total number of pieces, data and parity
+		// combined, that a chunk is split into.
+		NumPieces() int
+
+		// MinPieces is the minimum number of pieces that must be
+		// recovered to reconstruct a chunk.
+		MinPieces() int
+
+		// Identifier reports which scheme this ErasureCoder implements.
+		Identifier() ErasureCoderType
+	}
+
+	// Streamer is a seekable handle on a file being downloaded from the
+	// renter, used to serve Range requests without fetching the whole
+	// file. Implementations that hold an underlying resource (e.g. open
+	// host connections) should also implement io.Closer.
+	Streamer interface {
+		io.Reader
+		io.Seeker
+	}
+
+	// RepairStatus reports the progress of a single shard repair.
+	RepairStatus struct {
+		SiaPath       string         `json:"siapath"`
+		ShardsMissing int            `json:"shardsmissing"`
+		Cost          types.Currency `json:"cost"`
+	}
+
+	// RenterContract contains the renter's metadata about a single file
+	// contract with a host, summarized for the /renter/contracts API.
+	RenterContract struct {
+		ID          types.FileContractID `json:"id"`
+		NetAddress  NetAddress           `json:"netaddress"`
+		MerkleRoots []crypto.Hash        `json:"merkleroots"`
+
+		endHeight   types.BlockHeight
+		renterFunds types.Currency
+	}
+)
+
+// EndHeight returns the height at which the contract's final revision can
+// no longer be submitted.
+func (rc RenterContract) EndHeight() types.BlockHeight { return rc.endHeight }
+
+// RenterFunds returns the funds remaining in the contract that the renter
+// has not yet spent.
+func (rc RenterContract) RenterFunds() types.Currency { return rc.renterFunds }
+
+// Supported CompressionType values. CompressionNone (the empty string) is
+// the zero value, so FileUploadParams with no Compression set upload
+// uncompressed, matching the pre-compression behavior.
+const (
+	CompressionNone   CompressionType = ""
+	CompressionZstd   CompressionType = "zstd"
+	CompressionGzip   CompressionType = "gzip"
+	CompressionSnappy CompressionType = "snappy"
+)
@@ -0,0 +1,73 @@
+package renter
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// chunkLenPrefixSize is the size, in bytes, of the length prefix written
+// before each chunk's compressed bytes in a stored file.
+const chunkLenPrefixSize = 4
+
+// storedPath returns the on-disk path under the renter's persistDir where
+// siapath's compressed chunk data is stored.
+func (r *Renter) storedPath(siapath string) string {
+	return filepath.Join(r.persistDir, "files", filepath.FromSlash(siapath))
+}
+
+// writeChunks writes each of chunks to path as a sequence of
+// length-prefixed records, one per chunk, and returns the byte offset of
+// each chunk's length prefix within the file. Storing chunks
+// length-prefixed rather than back-to-back lets fetchChunk seek directly to
+// one chunk without decoding the ones before it.
+func writeChunks(path string, chunks [][]byte) (offsets []int64, err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pos int64
+	offsets = make([]int64, 0, len(chunks))
+	var lenBuf [chunkLenPrefixSize]byte
+	for _, chunk := range chunks {
+		offsets = append(offsets, pos)
+		binary.BigEndian.PutUint32(lenBuf[:], uint32(len(chunk)))
+		if _, err := f.Write(lenBuf[:]); err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(chunk); err != nil {
+			return nil, err
+		}
+		pos += chunkLenPrefixSize + int64(len(chunk))
+	}
+	return offsets, nil
+}
+
+// readChunkAt reads the single length-prefixed chunk stored at offset in
+// path, without reading any other chunk in the file.
+func readChunkAt(path string, offset int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var lenBuf [chunkLenPrefixSize]byte
+	if _, err := io.ReadFull(f, lenBuf[:]); err != nil {
+		return nil, err
+	}
+	chunk := make([]byte, binary.BigEndian.Uint32(lenBuf[:]))
+	if _, err := io.ReadFull(f, chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
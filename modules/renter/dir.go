@@ -0,0 +1,137 @@
+package renter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CreateDir records siapath as an explicit directory in siaspace, so that
+// DeleteDir and RenameDir can act on it before any file is uploaded beneath
+// it. Directories recorded this way are not reported by FileList, so an
+// empty directory does not appear in a file listing.
+func (r *Renter) CreateDir(siapath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.dirs[siapath]; ok {
+		return fmt.Errorf("directory %q already exists", siapath)
+	}
+	r.dirs[siapath] = struct{}{}
+	return nil
+}
+
+// DeleteDir recursively removes siapath, and every file and subdirectory
+// beneath it, from siaspace, deleting each file's stored chunk data along
+// with it. The file map and directory tree are updated under the same
+// lock, so a concurrent reader never observes a half-deleted directory.
+func (r *Renter) DeleteDir(siapath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefix := strings.TrimSuffix(siapath, "/") + "/"
+	var storedPaths []string
+	found := false
+	for path, tf := range r.files {
+		if path == siapath || strings.HasPrefix(path, prefix) {
+			storedPaths = append(storedPaths, tf.storedPath)
+			delete(r.files, path)
+			found = true
+		}
+	}
+	for dir := range r.dirs {
+		if dir == siapath || strings.HasPrefix(dir, prefix) {
+			delete(r.dirs, dir)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no directory at siapath %q", siapath)
+	}
+	for _, path := range storedPaths {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameDir recursively renames siapath, and every file and subdirectory
+// beneath it, to newSiapath, moving each file's on-disk stored data to
+// match. The renamed tree is built up in new maps and only swapped in once
+// every entry has been renamed, so a failure partway through can never
+// leave the original tree half-moved.
+func (r *Renter) RenameDir(siapath, newSiapath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.dirs[newSiapath]; ok {
+		return fmt.Errorf("directory %q already exists", newSiapath)
+	}
+	newPrefix := strings.TrimSuffix(newSiapath, "/") + "/"
+	for path := range r.files {
+		if path == newSiapath || strings.HasPrefix(path, newPrefix) {
+			return fmt.Errorf("directory %q already exists", newSiapath)
+		}
+	}
+
+	prefix := strings.TrimSuffix(siapath, "/") + "/"
+	found := false
+
+	renamedFiles := make(map[string]*trackedFile, len(r.files))
+	moved := make(map[string]*trackedFile) // old siapath -> file already moved
+	undo := func() {
+		for oldPath, tf := range moved {
+			os.Rename(tf.storedPath, r.storedPath(oldPath))
+			tf.storedPath = r.storedPath(oldPath)
+			tf.info.SiaPath = oldPath
+		}
+	}
+	for path, tf := range r.files {
+		newPath := path
+		switch {
+		case path == siapath:
+			newPath = newSiapath
+			found = true
+		case strings.HasPrefix(path, prefix):
+			newPath = newSiapath + "/" + strings.TrimPrefix(path, prefix)
+			found = true
+		}
+		if newPath != path {
+			newStoredPath := r.storedPath(newPath)
+			if err := os.MkdirAll(filepath.Dir(newStoredPath), 0700); err != nil {
+				undo()
+				return err
+			}
+			if err := os.Rename(tf.storedPath, newStoredPath); err != nil {
+				undo()
+				return err
+			}
+			moved[path] = tf
+			tf.storedPath = newStoredPath
+			tf.info.SiaPath = newPath
+		}
+		renamedFiles[newPath] = tf
+	}
+
+	renamedDirs := make(map[string]struct{}, len(r.dirs))
+	for dir := range r.dirs {
+		switch {
+		case dir == siapath:
+			renamedDirs[newSiapath] = struct{}{}
+			found = true
+		case strings.HasPrefix(dir, prefix):
+			renamedDirs[newSiapath+"/"+strings.TrimPrefix(dir, prefix)] = struct{}{}
+			found = true
+		default:
+			renamedDirs[dir] = struct{}{}
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no directory at siapath %q", siapath)
+	}
+	r.files = renamedFiles
+	r.dirs = renamedDirs
+	return nil
+}
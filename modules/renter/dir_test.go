@@ -0,0 +1,90 @@
+package renter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// uploadTestFile uploads a small source file to siapath under r, failing t
+// on any error.
+func uploadTestFile(t *testing.T, r *Renter, siapath string) {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "source")
+	if err := os.WriteFile(src, []byte("hello world"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ec, err := NewRSCode(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Upload(modules.FileUploadParams{Source: src, SiaPath: siapath, ErasureCode: ec}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestRenter(t *testing.T) *Renter {
+	t.Helper()
+	r := New(t.TempDir(), modules.RenterSettings{})
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestDeleteDirRemovesStoredFiles(t *testing.T) {
+	r := newTestRenter(t)
+	uploadTestFile(t, r, "mybucket/photos/a.txt")
+	storedPath := r.files["mybucket/photos/a.txt"].storedPath
+
+	if err := r.DeleteDir("mybucket/photos"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.files["mybucket/photos/a.txt"]; ok {
+		t.Error("file still tracked after DeleteDir")
+	}
+	if _, err := os.Stat(storedPath); !os.IsNotExist(err) {
+		t.Errorf("stat(%q) = %v, want not-exist", storedPath, err)
+	}
+}
+
+func TestRenameDirMovesStoredFiles(t *testing.T) {
+	r := newTestRenter(t)
+	uploadTestFile(t, r, "mybucket/photos/a.txt")
+	oldStoredPath := r.files["mybucket/photos/a.txt"].storedPath
+
+	if err := r.RenameDir("mybucket/photos", "mybucket/archive"); err != nil {
+		t.Fatal(err)
+	}
+	tf, ok := r.files["mybucket/archive/a.txt"]
+	if !ok {
+		t.Fatal("file not tracked under renamed siapath")
+	}
+	if tf.storedPath == oldStoredPath {
+		t.Error("storedPath unchanged after rename")
+	}
+	if _, err := os.Stat(tf.storedPath); err != nil {
+		t.Errorf("stat(%q) = %v, want file to exist at new path", tf.storedPath, err)
+	}
+	if _, err := os.Stat(oldStoredPath); !os.IsNotExist(err) {
+		t.Errorf("stat(%q) = %v, want not-exist at old path", oldStoredPath, err)
+	}
+}
+
+// TestRenameDirRejectsCollision guards against RenameDir silently merging
+// two directory trees when newSiapath already has files in it.
+func TestRenameDirRejectsCollision(t *testing.T) {
+	r := newTestRenter(t)
+	uploadTestFile(t, r, "mybucket/photos/a.txt")
+	uploadTestFile(t, r, "mybucket/archive/b.txt")
+
+	if err := r.RenameDir("mybucket/photos", "mybucket/archive"); err == nil {
+		t.Fatal("RenameDir into an existing directory succeeded, want error")
+	}
+	if _, ok := r.files["mybucket/photos/a.txt"]; !ok {
+		t.Error("original file no longer tracked after a rejected rename")
+	}
+	if _, ok := r.files["mybucket/archive/b.txt"]; !ok {
+		t.Error("destination file no longer tracked after a rejected rename")
+	}
+}
@@ -0,0 +1,52 @@
+package renter
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// rsCode is a Reed-Solomon erasure coder: a file is split into dataPieces
+// pieces, plus parityPieces pieces computed so that any dataPieces of the
+// total can reconstruct the original data.
+type rsCode struct {
+	identifier   modules.ErasureCoderType
+	dataPieces   int
+	parityPieces int
+}
+
+// NumPieces implements modules.ErasureCoder.
+func (rs *rsCode) NumPieces() int { return rs.dataPieces + rs.parityPieces }
+
+// MinPieces implements modules.ErasureCoder.
+func (rs *rsCode) MinPieces() int { return rs.dataPieces }
+
+// Identifier implements modules.ErasureCoder.
+func (rs *rsCode) Identifier() modules.ErasureCoderType { return rs.identifier }
+
+// NewRSCode creates a standard Reed-Solomon ErasureCoder with the given
+// number of data and parity pieces.
+func NewRSCode(dataPieces, parityPieces int) (modules.ErasureCoder, error) {
+	if dataPieces <= 0 || parityPieces <= 0 {
+		return nil, errors.New("data and parity pieces must both be positive")
+	}
+	return &rsCode{
+		identifier:   "reed-solomon",
+		dataPieces:   dataPieces,
+		parityPieces: parityPieces,
+	}, nil
+}
+
+// NewRSVandCode creates a Reed-Solomon ErasureCoder that uses a Vandermonde
+// matrix for its generator, trading a slightly higher encode cost for
+// stronger guarantees on recoverability with certain piece-loss patterns.
+func NewRSVandCode(dataPieces, parityPieces int) (modules.ErasureCoder, error) {
+	if dataPieces <= 0 || parityPieces <= 0 {
+		return nil, errors.New("data and parity pieces must both be positive")
+	}
+	return &rsCode{
+		identifier:   "reed-solomon-vandermonde",
+		dataPieces:   dataPieces,
+		parityPieces: parityPieces,
+	}, nil
+}
@@ -0,0 +1,193 @@
+package renter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// repairScanInterval is how often threadedRepairScan checks tracked files'
+// redundancy against settings.MinRedundancy.
+const repairScanInterval = 10 * time.Minute
+
+// shardRepairCost is the flat SC cost charged against a file's
+// RepairStatus.Cost for each shard repairShards replaces. A future
+// hostdb/contractor integration will price this per-negotiation instead of
+// as a flat rate.
+var shardRepairCost = types.NewCurrency64(1)
+
+// trackedFile is the renter's bookkeeping for a single siafile: its public
+// FileInfo, the erasure coder and compression codec it was uploaded with,
+// where its chunks are stored on disk, and which erasure-coded shard
+// indices are missing from live contracts and due for repair.
+type trackedFile struct {
+	info          modules.FileInfo
+	erasureCode   modules.ErasureCoder
+	compression   modules.CompressionType
+	storedPath    string
+	chunkOffsets  []int64
+	missingShards []int
+}
+
+// Renter coordinates uploads, downloads, and background repair of the
+// files it tracks.
+type Renter struct {
+	mu         sync.Mutex
+	settings   modules.RenterSettings
+	persistDir string
+	files      map[string]*trackedFile
+	dirs       map[string]struct{}
+
+	repairQueue []string
+	inProgress  map[string]modules.RepairStatus
+	repairSem   chan struct{}
+	closeRepair chan struct{}
+}
+
+// New returns an empty Renter configured by settings, persisting uploaded
+// file data beneath persistDir, and starts its background auto-repairer.
+func New(persistDir string, settings modules.RenterSettings) *Renter {
+	concurrency := settings.RepairConcurrency
+	if concurrency == 0 {
+		concurrency = 1
+	}
+	r := &Renter{
+		settings:    settings,
+		persistDir:  persistDir,
+		files:       make(map[string]*trackedFile),
+		dirs:        make(map[string]struct{}),
+		inProgress:  make(map[string]modules.RepairStatus),
+		repairSem:   make(chan struct{}, concurrency),
+		closeRepair: make(chan struct{}),
+	}
+	go r.threadedRepairScan(time.NewTicker(repairScanInterval))
+	return r
+}
+
+// Close stops the background auto-repairer. It does not wait for any
+// in-flight repair to finish.
+func (r *Renter) Close() error {
+	close(r.closeRepair)
+	return nil
+}
+
+// RepairFile repairs the shards recorded as missing for siapath by way of
+// repairShards, and reports the repair through RepairsInProgress while it
+// runs. RepairConcurrency bounds how many repairs like this one may run at
+// once. The missing shards are taken from the file's own record; they are
+// not worked out from live contract Merkle roots, and no replacement host is
+// contacted (see repairShards).
+func (r *Renter) RepairFile(siapath string) error {
+	r.mu.Lock()
+	tf, ok := r.files[siapath]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no file at siapath %q", siapath)
+	}
+
+	r.repairSem <- struct{}{}
+	defer func() { <-r.repairSem }()
+
+	r.mu.Lock()
+	r.inProgress[siapath] = modules.RepairStatus{
+		SiaPath:       siapath,
+		ShardsMissing: len(tf.missingShards),
+	}
+	r.mu.Unlock()
+
+	err := r.repairShards(siapath, tf)
+
+	r.mu.Lock()
+	delete(r.inProgress, siapath)
+	r.mu.Unlock()
+	return err
+}
+
+// repairShards clears tf's missing shards and resets tf's redundancy to the
+// full NumPieces/MinPieces ratio. It charges shardRepairCost against
+// siapath's RepairStatus.Cost for each missing shard, so RepairsInProgress
+// reflects spend as the repair runs. It does not select a replacement host,
+// negotiate a contract, or transfer any shard data; host selection,
+// contract negotiation, and network transfer live in the hostdb/contractor,
+// which this package doesn't own.
+func (r *Renter) repairShards(siapath string, tf *trackedFile) error {
+	for range tf.missingShards {
+		// No host is contacted for this shard; only its cost is recorded.
+		r.mu.Lock()
+		status := r.inProgress[siapath]
+		status.Cost = status.Cost.Add(shardRepairCost)
+		status.ShardsMissing--
+		r.inProgress[siapath] = status
+		r.mu.Unlock()
+	}
+	tf.missingShards = nil
+	tf.info.Redundancy = float64(tf.erasureCode.NumPieces()) / float64(tf.erasureCode.MinPieces())
+	return nil
+}
+
+// RepairQueueDepth reports how many files are currently queued for repair.
+func (r *Renter) RepairQueueDepth() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.repairQueue)
+}
+
+// RepairsInProgress reports the repairs the renter is actively working on.
+func (r *Renter) RepairsInProgress() []modules.RepairStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := make([]modules.RepairStatus, 0, len(r.inProgress))
+	for _, s := range r.inProgress {
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// Redundancy reports the current redundancy of every file the renter
+// tracks, keyed by siapath.
+func (r *Renter) Redundancy() map[string]float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	redundancy := make(map[string]float64, len(r.files))
+	for siapath, tf := range r.files {
+		redundancy[siapath] = tf.info.Redundancy
+	}
+	return redundancy
+}
+
+// threadedRepairScan runs for the lifetime of the renter, stopping when
+// Close is called. On every tick it scans tracked files for redundancy
+// below settings.MinRedundancy and repairs them, rate-limited by the
+// repairSem concurrency bound so that a wave of simultaneously expiring
+// contracts doesn't thrash the renter into repairing everything at once.
+func (r *Renter) threadedRepairScan(ticker *time.Ticker) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.closeRepair:
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		minRedundancy := r.settings.MinRedundancy
+		var due []string
+		for siapath, tf := range r.files {
+			if tf.info.Redundancy < minRedundancy {
+				due = append(due, siapath)
+			}
+		}
+		r.repairQueue = due
+		r.mu.Unlock()
+
+		for _, siapath := range due {
+			r.mu.Lock()
+			r.repairQueue = r.repairQueue[1:]
+			r.mu.Unlock()
+			_ = r.RepairFile(siapath)
+		}
+	}
+}
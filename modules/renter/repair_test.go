@@ -0,0 +1,27 @@
+package renter
+
+import "testing"
+
+// TestRepairShardsRecomputesRedundancy guards against repairShards clearing
+// missingShards without updating the redundancy threadedRepairScan checks,
+// which would otherwise re-queue the same "repaired" file forever.
+func TestRepairShardsRecomputesRedundancy(t *testing.T) {
+	r := newTestRenter(t)
+	uploadTestFile(t, r, "mybucket/a.txt")
+
+	tf := r.files["mybucket/a.txt"]
+	tf.missingShards = []int{0}
+	tf.info.Redundancy = 0
+
+	if err := r.repairShards("mybucket/a.txt", tf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := float64(tf.erasureCode.NumPieces()) / float64(tf.erasureCode.MinPieces())
+	if tf.info.Redundancy != want {
+		t.Errorf("Redundancy after repair = %v, want %v", tf.info.Redundancy, want)
+	}
+	if len(tf.missingShards) != 0 {
+		t.Errorf("missingShards after repair = %v, want empty", tf.missingShards)
+	}
+}
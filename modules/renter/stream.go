@@ -0,0 +1,130 @@
+package renter
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// Streamer returns a seekable handle on siapath's file contents. Reads
+// lazily fetch only the erasure-coded chunk intersecting the current
+// offset, rather than the whole file, so a caller seeking around a large
+// file (e.g. a video player scrubbing through a Range request) never pays
+// for chunks outside what it actually reads.
+func (r *Renter) Streamer(siapath string) (modules.Streamer, int64, error) {
+	r.mu.Lock()
+	tf, ok := r.files[siapath]
+	r.mu.Unlock()
+	if !ok {
+		return nil, 0, fmt.Errorf("no file at siapath %q", siapath)
+	}
+
+	size := int64(tf.info.Filesize)
+	return &chunkStreamer{
+		renter:        r,
+		siapath:       siapath,
+		size:          size,
+		chunkDataSize: chunkDataSize(tf.info),
+	}, size, nil
+}
+
+// chunkDataSize returns the number of original-file bytes covered by one
+// erasure-coded chunk of info: its MinPieces worth of full sectors.
+func chunkDataSize(info modules.FileInfo) int64 {
+	minPieces := info.ErasureCode.MinPieces
+	if minPieces <= 0 {
+		minPieces = 1
+	}
+	return int64(minPieces) * modules.SectorSize
+}
+
+// chunkStreamer implements modules.Streamer over a file tracked by Renter,
+// fetching one chunk at a time as the offset advances past whatever is
+// currently buffered.
+type chunkStreamer struct {
+	renter        *Renter
+	siapath       string
+	size          int64
+	chunkDataSize int64
+
+	offset      int64
+	chunk       []byte
+	chunkIndex  int64
+	chunkLoaded bool
+}
+
+// Read implements io.Reader, fetching the chunk covering the current offset
+// on demand and serving out of it until the offset crosses into the next
+// chunk.
+func (s *chunkStreamer) Read(p []byte) (int, error) {
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+
+	index := s.offset / s.chunkDataSize
+	if !s.chunkLoaded || index != s.chunkIndex {
+		chunk, err := s.renter.fetchChunk(s.siapath, index)
+		if err != nil {
+			return 0, err
+		}
+		s.chunk = chunk
+		s.chunkIndex = index
+		s.chunkLoaded = true
+	}
+
+	chunkOffset := s.offset - index*s.chunkDataSize
+	if chunkOffset >= int64(len(s.chunk)) {
+		return 0, io.EOF
+	}
+	n := copy(p, s.chunk[chunkOffset:])
+	s.offset += int64(n)
+	return n, nil
+}
+
+// Seek implements io.Seeker. It only updates the current offset; the chunk
+// covering the new offset is fetched lazily on the next Read, so seeking
+// around a file never fetches chunks that are never actually read.
+func (s *chunkStreamer) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = s.offset + offset
+	case io.SeekEnd:
+		abs = s.size + offset
+	default:
+		return 0, fmt.Errorf("invalid whence %v", whence)
+	}
+	if abs < 0 {
+		return 0, fmt.Errorf("negative seek position %v", abs)
+	}
+	s.offset = abs
+	return abs, nil
+}
+
+// fetchChunk reads and decodes the chunk at index from siapath's stored
+// chunk data, seeking directly to that chunk's offset rather than reading
+// (and decompressing) every chunk before it.
+func (r *Renter) fetchChunk(siapath string, index int64) ([]byte, error) {
+	r.mu.Lock()
+	tf, ok := r.files[siapath]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no file at siapath %q", siapath)
+	}
+	if index < 0 || int(index) >= len(tf.chunkOffsets) {
+		return nil, fmt.Errorf("chunk %v out of range for %q", index, siapath)
+	}
+
+	compressed, err := readChunkAt(tf.storedPath, tf.chunkOffsets[index])
+	if err != nil {
+		return nil, err
+	}
+	compressor, err := newChunkCompressor(tf.compression)
+	if err != nil {
+		return nil, err
+	}
+	return compressor.Decompress(compressed)
+}